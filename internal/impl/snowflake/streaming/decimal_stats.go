@@ -0,0 +1,135 @@
+/*
+ * Copyright 2024 Redpanda Data, Inc.
+ *
+ * Licensed as a Redpanda Enterprise file under the Redpanda Community
+ * License (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * https://github.com/redpanda-data/redpanda/blob/master/licenses/rcl.md
+ */
+
+package streaming
+
+import (
+	"strings"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/redpanda-data/connect/v4/internal/impl/snowflake/streaming/int128"
+)
+
+// narrowedByteWidth picks the physical byte width narrowPhysicalTypes should
+// use for field, based on the Int128 min/max bounds observed in stats.
+//
+// An all-null or entirely empty column leaves stats.minIntVal and
+// stats.maxIntVal at their zero value, which would otherwise look
+// indistinguishable from "every row was exactly 0" and narrow down to a
+// single byte - narrower than canCompatNumber allows, since SB1 isn't a
+// valid narrowing target. stats.first stays true until a row is observed,
+// so we use it to tell the two cases apart and keep the original width
+// rather than narrow a column we never actually saw any data for.
+func narrowedByteWidth(field parquet.Field, stats *statsBuffer) int {
+	if stats.first {
+		return byteWidthForPhysicalType(field)
+	}
+	return max(int128.ByteWidth(stats.maxIntVal), int128.ByteWidth(stats.minIntVal))
+}
+
+// byteWidthForPhysicalType recovers the byte width of field's current
+// physical type, for the cases where we choose to leave it unnarrowed.
+func byteWidthForPhysicalType(field parquet.Field) int {
+	switch field.Type().Kind() {
+	case parquet.Int32:
+		return 4
+	case parquet.Int64:
+		return 8
+	case parquet.FixedLenByteArray:
+		return field.Type().Length()
+	default:
+		return 16
+	}
+}
+
+// maxPrecisionForByteWidth already gives us the widest DECIMAL precision a
+// byte width can hold; tightestDecimalPrecision is its inverse, used when
+// narrowing a DECIMAL column down from SB16. It returns the smallest
+// precision that still fits both bounds observed in stats, without
+// exceeding the column's originally declared precision - narrowing storage
+// should never widen the number of significant digits a reader is told to
+// expect.
+//
+// An all-null or entirely empty column leaves stats.minIntVal and
+// stats.maxIntVal at their zero value, which would otherwise look
+// indistinguishable from "every row was exactly 0" and narrow precision down
+// to 1 regardless of what the column actually declares - the same
+// never-observed-a-row case narrowedByteWidth guards against. stats.first
+// keeps the original precision rather than narrow a column we never saw any
+// data for.
+func tightestDecimalPrecision(stats *statsBuffer, byteWidth int, originalPrecision int32) int32 {
+	if stats.first {
+		return min(originalPrecision, maxPrecisionForByteWidth(byteWidth))
+	}
+	bound := stats.maxIntVal
+	if stats.minIntVal.Abs().Cmp(bound.Abs()) > 0 {
+		bound = stats.minIntVal
+	}
+	precision := int32(len(bound.Abs().String()))
+	if precision < 1 {
+		precision = 1
+	}
+	return min(originalPrecision, maxPrecisionForByteWidth(byteWidth), max(precision, 1))
+}
+
+// isDecimalColumn reports whether column is a DECIMAL-annotated FIXED column
+// (i.e. both Scale and Precision are set), as opposed to a plain integer
+// FIXED column.
+func isDecimalColumn(column *columnMetadata) bool {
+	return strings.EqualFold(column.LogicalType, "fixed") && column.Scale != nil && column.Precision != nil
+}
+
+// leafKinds returns the physical Kind of every leaf column of schema, in
+// column-index order, so a caller can compare a row's values - each tagged
+// with its originating column index via parquet.Value.Column() - against
+// whatever schema they're about to be re-encoded for.
+func leafKinds(schema *parquet.Schema) []parquet.Kind {
+	paths := schema.Columns()
+	kinds := make([]parquet.Kind, len(paths))
+	for i, path := range paths {
+		leaf, ok := schema.Lookup(path...)
+		if !ok {
+			continue
+		}
+		kinds[i] = leaf.Node.Type().Kind()
+	}
+	return kinds
+}
+
+// narrowNumericValue re-encodes v, a numeric/decimal value read under its
+// original Kind, as dstKind - the Kind narrowPhysicalTypes picked for the
+// same column - preserving its null/definition/repetition levels. This is
+// the inverse of decodeFixed in reader.go: both go through int128.Int128 so
+// a 16-byte big-endian DECIMAL narrowed down to an Int64, for example, comes
+// out as the same number rather than its raw low/high bytes reinterpreted.
+func narrowNumericValue(v parquet.Value, dstKind parquet.Kind) parquet.Value {
+	if v.IsNull() {
+		return v
+	}
+	var n int128.Int128
+	switch v.Kind() {
+	case parquet.FixedLenByteArray:
+		n = int128.FromBigEndian(v.ByteArray())
+	case parquet.Int64:
+		n = int128.Int64(v.Int64())
+	default:
+		n = int128.Int64(int64(v.Int32()))
+	}
+	var out parquet.Value
+	switch dstKind {
+	case parquet.FixedLenByteArray:
+		out = parquet.FixedLenByteArrayValue(int128.ToBigEndian(n, 16))
+	case parquet.Int64:
+		out = parquet.Int64Value(n.ToInt64())
+	default:
+		out = parquet.Int32Value(int32(n.ToInt64()))
+	}
+	return out.Level(v.RepetitionLevel(), v.DefinitionLevel(), v.Column())
+}
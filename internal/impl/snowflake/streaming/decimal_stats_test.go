@@ -0,0 +1,144 @@
+/*
+ * Copyright 2024 Redpanda Data, Inc.
+ *
+ * Licensed as a Redpanda Enterprise file under the Redpanda Community
+ * License (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * https://github.com/redpanda-data/redpanda/blob/master/licenses/rcl.md
+ */
+
+package streaming
+
+import (
+	"testing"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/redpanda-data/connect/v4/internal/impl/snowflake/streaming/int128"
+)
+
+func TestNarrowedByteWidthKeepsOriginalWidthWhenNoRowsObserved(t *testing.T) {
+	field := parquet.NewSchema("test", parquet.Group{
+		"n": parquet.Leaf(parquet.Int64Type),
+	}).Fields()[0]
+	stats := &statsBuffer{first: true}
+	if got := narrowedByteWidth(field, stats); got != 8 {
+		t.Errorf("narrowedByteWidth() = %d, want 8 (unnarrowed Int64 width)", got)
+	}
+}
+
+func TestNarrowedByteWidthNarrowsToObservedBounds(t *testing.T) {
+	field := parquet.NewSchema("test", parquet.Group{
+		"n": parquet.Leaf(parquet.Int64Type),
+	}).Fields()[0]
+	stats := &statsBuffer{minIntVal: int128.Int64(-1), maxIntVal: int128.Int64(100)}
+	if got := narrowedByteWidth(field, stats); got != 1 {
+		t.Errorf("narrowedByteWidth() = %d, want 1 (100 fits in a single byte)", got)
+	}
+}
+
+func TestIsDecimalColumn(t *testing.T) {
+	scale, precision := int32(2), int32(9)
+	tests := []struct {
+		name string
+		col  *columnMetadata
+		want bool
+	}{
+		{"decimal fixed", &columnMetadata{LogicalType: "fixed", Scale: &scale, Precision: &precision}, true},
+		{"plain fixed integer", &columnMetadata{LogicalType: "fixed"}, false},
+		{"non-fixed type", &columnMetadata{LogicalType: "text", Scale: &scale, Precision: &precision}, false},
+	}
+	for _, tc := range tests {
+		if got := isDecimalColumn(tc.col); got != tc.want {
+			t.Errorf("%s: isDecimalColumn() = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestTightestDecimalPrecisionKeepsOriginalWhenNoRowsObserved(t *testing.T) {
+	stats := &statsBuffer{first: true}
+	if got := tightestDecimalPrecision(stats, 16, 18); got != 18 {
+		t.Errorf("tightestDecimalPrecision() = %d, want the original precision 18 (no rows observed)", got)
+	}
+}
+
+// TestNarrowPhysicalTypesKeepsOriginalPrecisionForEmptyDecimalColumn covers
+// the bug where an all-null DECIMAL column narrowed its declared precision
+// down to 1: stats.minIntVal/maxIntVal are both the int128 zero value whether
+// the column held all zeros or no rows at all, so narrowPhysicalTypes must
+// consult stats.first rather than the bounds themselves to tell the two apart.
+func TestNarrowPhysicalTypesKeepsOriginalPrecisionForEmptyDecimalColumn(t *testing.T) {
+	scale, precision := int32(2), int32(18)
+	name := "amount"
+	columns := []columnMetadata{
+		{Name: name, Ordinal: 1, LogicalType: "fixed", PhysicalType: "SB16", Nullable: true, Scale: &scale, Precision: &precision},
+	}
+	schema, transformers, typeMetadata, err := constructParquetSchema(columns, EncodingConfig{})
+	if err != nil {
+		t.Fatalf("constructParquetSchema returned error: %v", err)
+	}
+	transformer := transformers[normalizeColumnName(name)]
+	transformer.stats.first = true // no rows were ever observed for this column
+
+	narrowedSchema, _ := narrowPhysicalTypes(schema, transformers, typeMetadata, EncodingConfig{})
+	field, ok := narrowedSchema.Lookup(normalizeColumnName(name))
+	if !ok {
+		t.Fatalf("narrowed schema is missing column %q", normalizeColumnName(name))
+	}
+	lt := field.Node.Type().LogicalType()
+	if lt == nil || lt.Decimal == nil {
+		t.Fatal("expected the narrowed column to stay DECIMAL-annotated")
+	}
+	if got := int32(lt.Decimal.Precision); got != precision {
+		t.Errorf("narrowed Precision = %d, want the original %d (no rows observed)", got, precision)
+	}
+}
+
+func TestTightestDecimalPrecision(t *testing.T) {
+	stats := &statsBuffer{
+		minIntVal: int128.Int64(-12),
+		maxIntVal: int128.Int64(1234),
+	}
+	got := tightestDecimalPrecision(stats, 2, 18)
+	if got != 4 {
+		t.Errorf("tightestDecimalPrecision() = %d, want 4 (len(\"1234\"))", got)
+	}
+}
+
+func TestTightestDecimalPrecisionNeverExceedsOriginal(t *testing.T) {
+	stats := &statsBuffer{
+		minIntVal: int128.Int64(0),
+		maxIntVal: int128.Int64(1234),
+	}
+	if got := tightestDecimalPrecision(stats, 2, 3); got != 3 {
+		t.Errorf("tightestDecimalPrecision() = %d, want the original precision 3 (1234 has 4 digits)", got)
+	}
+}
+
+// TestNarrowNumericValueRoundTrips covers the bug in rewriteNarrowed: naively
+// copying a parquet.Value from a wide Kind into a writer expecting a
+// narrowed Kind misreads the raw bytes instead of producing the same number.
+func TestNarrowNumericValueRoundTrips(t *testing.T) {
+	wide := parquet.Int64Value(12345).Level(0, 1, 3)
+	narrow := narrowNumericValue(wide, parquet.Int32)
+	if narrow.Kind() != parquet.Int32 {
+		t.Fatalf("expected narrowed Kind Int32, got %v", narrow.Kind())
+	}
+	if narrow.Int32() != 12345 {
+		t.Errorf("narrowNumericValue lost the value: got %d, want 12345", narrow.Int32())
+	}
+	if narrow.Column() != 3 || narrow.DefinitionLevel() != 1 {
+		t.Errorf("narrowNumericValue should preserve levels/column, got column=%d def=%d", narrow.Column(), narrow.DefinitionLevel())
+	}
+}
+
+func TestNarrowNumericValuePreservesNull(t *testing.T) {
+	null := parquet.Value{}.Level(0, 0, 1)
+	if !null.IsNull() {
+		t.Fatal("test setup: expected a null value")
+	}
+	got := narrowNumericValue(null, parquet.Int32)
+	if !got.IsNull() {
+		t.Error("narrowNumericValue should leave a null value untouched")
+	}
+}
@@ -0,0 +1,227 @@
+/*
+ * Copyright 2024 Redpanda Data, Inc.
+ *
+ * Licensed as a Redpanda Enterprise file under the Redpanda Community
+ * License (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * https://github.com/redpanda-data/redpanda/blob/master/licenses/rcl.md
+ */
+
+package streaming
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"strings"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/format"
+	"github.com/redpanda-data/connect/v4/internal/impl/snowflake/streaming/int128"
+)
+
+// dictionaryCardinalityThreshold is the maximum estimated number of distinct
+// values a column may have before we stop considering it low-cardinality
+// enough for dictionary encoding.
+const dictionaryCardinalityThreshold = 1 << 16
+
+// EncodingPolicy picks the parquet encoding for a column, given the metadata
+// that describes it and whatever stats have been accumulated for it so far.
+// It's consulted both when a column's schema is first constructed (with nil
+// stats) and again once narrowPhysicalTypes has a real sample to work with.
+type EncodingPolicy interface {
+	SelectEncoding(column *columnMetadata, stats *statsBuffer) parquet.Encoding
+}
+
+// EncodingConfig wires an EncodingPolicy into constructParquetSchema, with
+// room for callers to pin specific columns of specific tables to a fixed
+// encoding regardless of what the policy would otherwise pick.
+type EncodingConfig struct {
+	// Policy selects an encoding per-column. A nil Policy is equivalent to
+	// &defaultEncodingPolicy{}.
+	Policy EncodingPolicy
+	// Overrides maps a normalized column name to the encoding that must be
+	// used for it, taking priority over Policy. This is how per-table
+	// overrides are threaded through: callers build one EncodingConfig per
+	// table from its configuration before calling constructParquetSchema.
+	Overrides map[string]parquet.Encoding
+}
+
+func (cfg EncodingConfig) policy() EncodingPolicy {
+	if cfg.Policy != nil {
+		return cfg.Policy
+	}
+	return &defaultEncodingPolicy{}
+}
+
+// selectEncoding resolves the encoding for column, honoring cfg.Overrides
+// before falling back to cfg.Policy, and finally gating the result against
+// encodings Snowflake's BDEC reader doesn't accept.
+func (cfg EncodingConfig) selectEncoding(column *columnMetadata, stats *statsBuffer) parquet.Encoding {
+	name := normalizeColumnName(column.Name)
+	if e, ok := cfg.Overrides[name]; ok {
+		return compatibleEncoding(column, e)
+	}
+	return compatibleEncoding(column, cfg.policy().SelectEncoding(column, stats))
+}
+
+// defaultEncodingPolicy implements these heuristics: DICTIONARY for low-cardinality TEXT/FIXED columns, DELTA_BINARY_PACKED
+// for monotonic or narrow-range integers, RLE_DICTIONARY for booleans and
+// enum-like strings, and PLAIN otherwise.
+type defaultEncodingPolicy struct{}
+
+func (defaultEncodingPolicy) SelectEncoding(column *columnMetadata, stats *statsBuffer) parquet.Encoding {
+	logicalType := strings.ToLower(column.LogicalType)
+	switch logicalType {
+	case "boolean":
+		return &parquet.RLEDictionary
+	case "text", "char", "any", "fixed":
+		if stats != nil && stats.isLowCardinality(dictionaryCardinalityThreshold) {
+			return &parquet.RLEDictionary
+		}
+		if logicalType == "fixed" && stats != nil && stats.isNarrowRange() {
+			return &parquet.DeltaBinaryPacked
+		}
+	}
+	return &parquet.Plain
+}
+
+// isLowCardinality reports whether the estimated number of distinct values
+// observed so far for the column is at most threshold. The estimate comes
+// from cardinalitySketch, a HyperLogLog-style approximation, so it is not an
+// exact count; before any value has been observed this reports false.
+func (s *statsBuffer) isLowCardinality(threshold int) bool {
+	if s == nil || s.distinct == nil {
+		return false
+	}
+	return s.distinct.estimate() <= uint64(threshold)
+}
+
+// isNarrowRange reports whether the span between the observed min and max
+// integer bounds needs fewer bytes than the max bound itself, i.e. the values
+// sit close together relative to their magnitude, which is where
+// DELTA_BINARY_PACKED is likely to beat PLAIN. It reports false before any
+// row has been observed.
+func (s *statsBuffer) isNarrowRange() bool {
+	if s == nil || s.first {
+		return false
+	}
+	span := s.maxIntVal.Sub(s.minIntVal)
+	return int128.ByteWidth(span) < int128.ByteWidth(s.maxIntVal)
+}
+
+// cardinalitySketch is a HyperLogLog-style approximate distinct counter used
+// to decide whether a column is a good candidate for dictionary encoding
+// without having to materialize the full set of distinct values it's seen.
+// registers is sized small on purpose - we only need to distinguish
+// "clearly low cardinality" from "clearly not", not an exact count.
+type cardinalitySketch struct {
+	registers [1 << 10]uint8
+}
+
+func newCardinalitySketch() *cardinalitySketch {
+	return &cardinalitySketch{}
+}
+
+func (c *cardinalitySketch) add(value []byte) {
+	h := hashFNV1a(value)
+	bucket := h & (uint64(len(c.registers)) - 1)
+	rho := uint8(bits.TrailingZeros64(h>>10) + 1)
+	if rho > c.registers[bucket] {
+		c.registers[bucket] = rho
+	}
+}
+
+func (c *cardinalitySketch) estimate() uint64 {
+	m := float64(len(c.registers))
+	sum := 0.0
+	zeros := 0
+	for _, r := range c.registers {
+		sum += 1 / math.Pow(2, float64(r))
+		if r == 0 {
+			zeros++
+		}
+	}
+	alpha := 0.7213 / (1 + 1.079/m)
+	raw := alpha * m * m / sum
+	if raw <= 2.5*m && zeros > 0 {
+		return uint64(m * math.Log(m/float64(zeros)))
+	}
+	return uint64(raw)
+}
+
+func hashFNV1a(data []byte) uint64 {
+	const offset64 = 14695981039346656037
+	const prime64 = 1099511628211
+	h := uint64(offset64)
+	for _, b := range data {
+		h ^= uint64(b)
+		h *= prime64
+	}
+	return h
+}
+
+// compatibleEncoding gates e against the encodings Snowflake's BDEC reader
+// is known to accept for column's physical type, falling back to PLAIN for
+// anything unsupported rather than emitting a file Snowflake can't ingest.
+func compatibleEncoding(column *columnMetadata, e parquet.Encoding) parquet.Encoding {
+	if e == nil {
+		return &parquet.Plain
+	}
+	switch strings.ToUpper(column.PhysicalType) {
+	case "SB16":
+		// Snowflake's BDEC reader only accepts PLAIN for 16-byte fixed
+		// binary columns today. LOB columns (TEXT/BINARY) aren't gated
+		// here - that used to also force every TEXT column back to PLAIN,
+		// which defeated the DICTIONARY-for-low-cardinality-TEXT encoding
+		// defaultEncodingPolicy picks.
+		switch e.Encoding() {
+		case format.Plain:
+			return e
+		default:
+			return &parquet.Plain
+		}
+	}
+	return e
+}
+
+// cardinalityEligible reports whether column is one defaultEncodingPolicy
+// actually consults isLowCardinality for, so observeCardinality isn't
+// wasting a sketch's memory on every other column too.
+func cardinalityEligible(column *columnMetadata) bool {
+	switch strings.ToLower(column.LogicalType) {
+	case "text", "char", "any", "fixed":
+		return true
+	}
+	return false
+}
+
+// observeCardinality feeds value into stats.distinct, lazily creating the
+// sketch on first use. Without this, distinct stays nil forever and
+// isLowCardinality always reports false, so DICTIONARY encoding for
+// low-cardinality TEXT/FIXED columns never fires no matter how repetitive
+// the data is.
+func observeCardinality(stats *statsBuffer, value any) {
+	if value == nil {
+		return
+	}
+	if stats.distinct == nil {
+		stats.distinct = newCardinalitySketch()
+	}
+	stats.distinct.add(cardinalityKey(value))
+}
+
+// cardinalityKey renders value as the byte string cardinalitySketch hashes,
+// reusing the raw bytes directly for the common []byte/string cases instead
+// of going through fmt for every row.
+func cardinalityKey(value any) []byte {
+	switch v := value.(type) {
+	case []byte:
+		return v
+	case string:
+		return []byte(v)
+	default:
+		return []byte(fmt.Sprint(v))
+	}
+}
@@ -0,0 +1,133 @@
+/*
+ * Copyright 2024 Redpanda Data, Inc.
+ *
+ * Licensed as a Redpanda Enterprise file under the Redpanda Community
+ * License (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * https://github.com/redpanda-data/redpanda/blob/master/licenses/rcl.md
+ */
+
+package streaming
+
+import (
+	"fmt"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/deprecated"
+)
+
+// columnMetadataFromParquet builds the columnMetadata that constructParquetSchema
+// expects from an arbitrary, user-supplied parquet schema, so that files
+// written by older tooling that only populate the deprecated ConvertedType
+// (rather than the modern LogicalType) can still be ingested into Snowflake.
+//
+// Each leaf's ConvertedType is promoted to the LogicalType/PhysicalType pair
+// Snowflake understands, preserving scale and precision where present, so
+// that the same dataTransformer converters used for LogicalType-aware
+// schemas work uniformly on both new and legacy sources.
+func columnMetadataFromParquet(schema *parquet.Schema) ([]columnMetadata, error) {
+	columns := make([]columnMetadata, 0, len(schema.Fields()))
+	for i, field := range schema.Fields() {
+		column, err := columnMetadataFromLeaf(field)
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %w", field.Name(), err)
+		}
+		if column.Ordinal == 0 {
+			column.Ordinal = int32(i + 1)
+		}
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+// columnMetadataFromLeaf promotes a single leaf field's type information -
+// preferring a modern LogicalType if one is present, otherwise falling back
+// to the deprecated ConvertedType - into a columnMetadata understood by
+// constructParquetSchema.
+func columnMetadataFromLeaf(field parquet.Field) (columnMetadata, error) {
+	column := columnMetadata{
+		Name:     field.Name(),
+		Nullable: field.Optional(),
+	}
+	if id := field.ID(); id != 0 {
+		column.Ordinal = int32(id)
+	}
+	typ := field.Type()
+	if lt := typ.LogicalType(); lt != nil {
+		// Already a modern schema - constructParquetSchema only needs the
+		// logical/physical type names, which we can derive the same way the
+		// ConvertedType promotion below does, by inspecting the annotation.
+		switch {
+		case lt.UTF8 != nil:
+			column.LogicalType, column.PhysicalType = "TEXT", "LOB"
+		case lt.Decimal != nil:
+			scale, precision := int32(lt.Decimal.Scale), int32(lt.Decimal.Precision)
+			column.Scale, column.Precision = &scale, &precision
+			column.LogicalType, column.PhysicalType = "FIXED", physicalTypeForPrecision(precision)
+		case lt.Date != nil:
+			column.LogicalType, column.PhysicalType = "DATE", "SB4"
+		case lt.Time != nil:
+			column.LogicalType, column.PhysicalType = "TIME", "SB4"
+		case lt.Timestamp != nil:
+			column.LogicalType, column.PhysicalType = "TIMESTAMP_NTZ", "SB8"
+		default:
+			return columnMetadata{}, fmt.Errorf("unsupported logical type for field %s", field.Name())
+		}
+		return column, nil
+	}
+
+	ct := typ.ConvertedType()
+	if ct == nil {
+		return columnMetadata{}, fmt.Errorf("field %s has neither a LogicalType nor a ConvertedType", field.Name())
+	}
+	decimalScale, decimalPrecision := typ.Decimal()
+	logicalType, physicalType, scale, precision, err := promoteConvertedType(*ct, int32(decimalScale), int32(decimalPrecision))
+	if err != nil {
+		return columnMetadata{}, fmt.Errorf("field %s: %w", field.Name(), err)
+	}
+	column.LogicalType, column.PhysicalType = logicalType, physicalType
+	column.Scale, column.Precision = scale, precision
+	return column, nil
+}
+
+// promoteConvertedType maps a single deprecated.ConvertedType ordinal to the
+// LogicalType/PhysicalType pair Snowflake understands, split out of
+// columnMetadataFromLeaf so each case can be exercised directly without
+// having to construct a parquet.Field carrying that exact annotation.
+// decimalScale/decimalPrecision are only consulted for deprecated.Decimal.
+func promoteConvertedType(ct deprecated.ConvertedType, decimalScale, decimalPrecision int32) (logicalType, physicalType string, scale, precision *int32, err error) {
+	switch ct {
+	case deprecated.UTF8:
+		return "TEXT", "LOB", nil, nil, nil
+	case deprecated.Decimal:
+		s, p := decimalScale, decimalPrecision
+		return "FIXED", physicalTypeForPrecision(p), &s, &p, nil
+	case deprecated.Date:
+		return "DATE", "SB4", nil, nil, nil
+	case deprecated.TimeMillis:
+		s := int32(3)
+		return "TIME", "SB4", &s, nil, nil
+	case deprecated.TimestampMicros:
+		s := int32(6)
+		return "TIMESTAMP_NTZ", "SB8", &s, nil, nil
+	case deprecated.Enum:
+		return "TEXT", "LOB", nil, nil, nil
+	case deprecated.Interval:
+		return "BINARY", "LOB", nil, nil, nil
+	default:
+		return "", "", nil, nil, fmt.Errorf("unsupported ConvertedType %v", ct)
+	}
+}
+
+// physicalTypeForPrecision picks the smallest SBn physical storage type that
+// can hold a DECIMAL with the given precision, mirroring how Snowflake sizes
+// FIXED columns based on maxPrecisionForByteWidth.
+func physicalTypeForPrecision(precision int32) string {
+	for _, width := range []int{1, 2, 4, 8, 16} {
+		if precision <= maxPrecisionForByteWidth(width) {
+			return fmt.Sprintf("SB%d", width)
+		}
+	}
+	return "SB16"
+}
@@ -0,0 +1,117 @@
+/*
+ * Copyright 2024 Redpanda Data, Inc.
+ *
+ * Licensed as a Redpanda Enterprise file under the Redpanda Community
+ * License (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * https://github.com/redpanda-data/redpanda/blob/master/licenses/rcl.md
+ */
+
+package streaming
+
+import (
+	"testing"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/deprecated"
+)
+
+func TestPhysicalTypeForPrecision(t *testing.T) {
+	tests := []struct {
+		precision int32
+		want      string
+	}{
+		{1, "SB1"},
+		{3, "SB1"},
+		{4, "SB2"},
+		{9, "SB4"},
+		{18, "SB8"},
+		{38, "SB16"},
+		{39, "SB16"},
+	}
+	for _, tc := range tests {
+		if got := physicalTypeForPrecision(tc.precision); got != tc.want {
+			t.Errorf("physicalTypeForPrecision(%d) = %q, want %q", tc.precision, got, tc.want)
+		}
+	}
+}
+
+func TestColumnMetadataFromLeafUnsupported(t *testing.T) {
+	// A bare boolean leaf carries neither a LogicalType nor a
+	// ConvertedType annotation, so columnMetadataFromLeaf can't promote it -
+	// it should report that rather than return a zero-value columnMetadata
+	// that looks like a valid column.
+	schema := parquet.NewSchema("test", parquet.Group{
+		"mystery": parquet.Leaf(parquet.BooleanType),
+	})
+	if _, err := columnMetadataFromLeaf(schema.Fields()[0]); err == nil {
+		t.Fatal("expected an error for a field with no type information")
+	}
+}
+
+// TestPromoteConvertedType exercises every deprecated.ConvertedType case
+// columnMetadataFromLeaf falls back to when a field carries no modern
+// LogicalType - the legacy promotion path the request asked for, which
+// shipped with no coverage at all.
+func TestPromoteConvertedType(t *testing.T) {
+	three, six := int32(3), int32(6)
+	scale, precision := int32(2), int32(9)
+	tests := []struct {
+		name             string
+		ct               deprecated.ConvertedType
+		decimalScale     int32
+		decimalPrecision int32
+		wantLogicalType  string
+		wantPhysicalType string
+		wantScale        *int32
+		wantPrecision    *int32
+	}{
+		{name: "UTF8", ct: deprecated.UTF8, wantLogicalType: "TEXT", wantPhysicalType: "LOB"},
+		{name: "Decimal", ct: deprecated.Decimal, decimalScale: scale, decimalPrecision: precision, wantLogicalType: "FIXED", wantPhysicalType: "SB4", wantScale: &scale, wantPrecision: &precision},
+		{name: "Date", ct: deprecated.Date, wantLogicalType: "DATE", wantPhysicalType: "SB4"},
+		{name: "TimeMillis", ct: deprecated.TimeMillis, wantLogicalType: "TIME", wantPhysicalType: "SB4", wantScale: &three},
+		{name: "TimestampMicros", ct: deprecated.TimestampMicros, wantLogicalType: "TIMESTAMP_NTZ", wantPhysicalType: "SB8", wantScale: &six},
+		{name: "Enum", ct: deprecated.Enum, wantLogicalType: "TEXT", wantPhysicalType: "LOB"},
+		{name: "Interval", ct: deprecated.Interval, wantLogicalType: "BINARY", wantPhysicalType: "LOB"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			logicalType, physicalType, gotScale, gotPrecision, err := promoteConvertedType(tc.ct, tc.decimalScale, tc.decimalPrecision)
+			if err != nil {
+				t.Fatalf("promoteConvertedType returned error: %v", err)
+			}
+			if logicalType != tc.wantLogicalType {
+				t.Errorf("LogicalType = %q, want %q", logicalType, tc.wantLogicalType)
+			}
+			if physicalType != tc.wantPhysicalType {
+				t.Errorf("PhysicalType = %q, want %q", physicalType, tc.wantPhysicalType)
+			}
+			if (gotScale == nil) != (tc.wantScale == nil) || (gotScale != nil && *gotScale != *tc.wantScale) {
+				t.Errorf("Scale = %v, want %v", gotScale, tc.wantScale)
+			}
+			if (gotPrecision == nil) != (tc.wantPrecision == nil) || (gotPrecision != nil && *gotPrecision != *tc.wantPrecision) {
+				t.Errorf("Precision = %v, want %v", gotPrecision, tc.wantPrecision)
+			}
+		})
+	}
+}
+
+func TestColumnMetadataFromLeafDecimal(t *testing.T) {
+	schema := parquet.NewSchema("test", parquet.Group{
+		"amount": parquet.Decimal(2, 9, parquet.Int32Type),
+	})
+	column, err := columnMetadataFromLeaf(schema.Fields()[0])
+	if err != nil {
+		t.Fatalf("columnMetadataFromLeaf returned error: %v", err)
+	}
+	if column.LogicalType != "FIXED" {
+		t.Errorf("LogicalType = %q, want FIXED", column.LogicalType)
+	}
+	if column.Scale == nil || *column.Scale != 2 {
+		t.Errorf("Scale = %v, want 2", column.Scale)
+	}
+	if column.PhysicalType != "SB4" {
+		t.Errorf("PhysicalType = %q, want SB4 (precision 9 fits in 4 bytes)", column.PhysicalType)
+	}
+}
@@ -0,0 +1,229 @@
+/*
+ * Copyright 2024 Redpanda Data, Inc.
+ *
+ * Licensed as a Redpanda Enterprise file under the Redpanda Community
+ * License (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * https://github.com/redpanda-data/redpanda/blob/master/licenses/rcl.md
+ */
+
+package streaming
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// structuredConverter is the dataConverter for a structured ARRAY/OBJECT
+// column built by constructArrayNode/constructObjectNode. Unlike the scalar
+// converters, it never writes into a typedBuffer: node is this column's own
+// single-field schema (the same node constructParquetSchema put into the
+// top-level group), and ValidateAndConvert uses its Deconstruct to turn a Go
+// slice/map value directly into the flat run of parquet.Values this column
+// contributes to a row, stashing the result on self.rows for writeBufferedRow
+// to splice in. Validation and stats collection still go through the
+// per-element/per-field converters constructArrayNode/constructObjectNode
+// built, the same way a flat column's converter would populate its own
+// stats.
+type structuredConverter struct {
+	self     *dataTransformer
+	column   columnMetadata
+	name     string
+	schema   *parquet.Schema
+	children map[string]*dataTransformer
+	order    []string // struct field order; nil for arrays and maps
+}
+
+// newStructuredConverter builds the converter for a structured column, given
+// node - the already-wrapped (Optional/FieldID/Encoded) node that was placed
+// into the parent group for this column - so the schema it deconstructs
+// against matches exactly what the writer will see.
+func newStructuredConverter(self *dataTransformer, column columnMetadata, node parquet.Node, children map[string]*dataTransformer, order []string) *structuredConverter {
+	name := normalizeColumnName(column.Name)
+	return &structuredConverter{
+		self:     self,
+		column:   column,
+		name:     name,
+		schema:   parquet.NewSchema(name, parquet.Group{name: node}),
+		children: children,
+		order:    order,
+	}
+}
+
+func (c *structuredConverter) ValidateAndConvert(stats *statsBuffer, value any, _ *typedBuffer) error {
+	var converted any
+	if value == nil {
+		if !c.column.Nullable {
+			return fmt.Errorf("column %s: null value for non-nullable column", c.column.Name)
+		}
+		stats.nullCount++
+	} else {
+		var err error
+		converted, err = c.convertChildren(value)
+		if err != nil {
+			return fmt.Errorf("column %s: %w", c.column.Name, err)
+		}
+	}
+	row, err := c.schema.Deconstruct(nil, map[string]any{c.name: converted})
+	if err != nil {
+		return fmt.Errorf("column %s: encoding structured value: %w", c.column.Name, err)
+	}
+	c.self.rows = append(c.self.rows, row)
+	return nil
+}
+
+// convertChildren walks value the same way Deconstruct is about to, running
+// it through the per-element/per-field converters and returning the shape
+// Deconstruct expects rebuilt from each child's own converted output - not
+// value itself. A FIXED/TIMESTAMP/TIME/DATE element needs its converter's
+// scaling and byte width applied before it's written; handing Deconstruct the
+// raw Go value would instead run it through parquet-go's generic reflection
+// encoding, which knows nothing about Snowflake's scaled-integer layout.
+func (c *structuredConverter) convertChildren(value any) (any, error) {
+	switch strings.ToLower(c.column.LogicalType) {
+	case "array":
+		return c.convertArray(value)
+	case "object":
+		if len(c.order) > 0 {
+			return c.convertStruct(value)
+		}
+		return c.convertMap(value)
+	default:
+		return nil, fmt.Errorf("unsupported structured logical type %s", c.column.LogicalType)
+	}
+}
+
+func (c *structuredConverter) convertArray(value any) ([]any, error) {
+	elems, err := toAnySlice(value)
+	if err != nil {
+		return nil, err
+	}
+	element := c.children["element"]
+	out := make([]any, len(elems))
+	for i, e := range elems {
+		if err := element.converter.ValidateAndConvert(element.stats, e, &element.buf); err != nil {
+			return nil, fmt.Errorf("element %d: %w", i, err)
+		}
+		out[i] = nativeValue(element.buf.Last())
+	}
+	return out, nil
+}
+
+func (c *structuredConverter) convertStruct(value any) (map[string]any, error) {
+	fields, err := toFieldMap(value)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]any, len(c.order))
+	for _, name := range c.order {
+		child := c.children[name]
+		if err := child.converter.ValidateAndConvert(child.stats, fields[name], &child.buf); err != nil {
+			return nil, fmt.Errorf("field %s: %w", name, err)
+		}
+		out[name] = nativeValue(child.buf.Last())
+	}
+	return out, nil
+}
+
+// convertMap rebuilds value as a map[string]any, keeping the caller's
+// original string key (map keys in a Snowflake OBJECT-as-MAP column are
+// always TEXT, so unlike the value there's no scaled encoding to preserve)
+// but replacing the value with the one the value converter actually wrote.
+func (c *structuredConverter) convertMap(value any) (map[string]any, error) {
+	entries, err := toMapEntries(value)
+	if err != nil {
+		return nil, err
+	}
+	key, val := c.children["key"], c.children["value"]
+	out := make(map[string]any, len(entries))
+	for _, e := range entries {
+		if err := key.converter.ValidateAndConvert(key.stats, e.key, &key.buf); err != nil {
+			return nil, fmt.Errorf("map key: %w", err)
+		}
+		if err := val.converter.ValidateAndConvert(val.stats, e.value, &val.buf); err != nil {
+			return nil, fmt.Errorf("map value %v: %w", e.key, err)
+		}
+		keyStr, ok := e.key.(string)
+		if !ok {
+			return nil, fmt.Errorf("map key %v: expected a string key", e.key)
+		}
+		out[keyStr] = nativeValue(val.buf.Last())
+	}
+	return out, nil
+}
+
+// nativeValue extracts the Go value matching v's physical Kind, so it can be
+// fed back through parquet.Schema.Deconstruct carrying whatever encoding
+// (DECIMAL scaling, timestamp scale, FixedLenByteArray width, ...) the owning
+// converter already applied to it.
+func nativeValue(v parquet.Value) any {
+	if v.IsNull() {
+		return nil
+	}
+	switch v.Kind() {
+	case parquet.Boolean:
+		return v.Boolean()
+	case parquet.Int32:
+		return v.Int32()
+	case parquet.Int64:
+		return v.Int64()
+	case parquet.Double:
+		return v.Double()
+	case parquet.ByteArray, parquet.FixedLenByteArray:
+		return v.ByteArray()
+	default:
+		return nil
+	}
+}
+
+// toAnySlice normalizes value, which should be a Go slice produced by a
+// caller filling in an ARRAY column, to []any.
+func toAnySlice(value any) ([]any, error) {
+	if s, ok := value.([]any); ok {
+		return s, nil
+	}
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, fmt.Errorf("expected an array value, got %T", value)
+	}
+	out := make([]any, rv.Len())
+	for i := range out {
+		out[i] = rv.Index(i).Interface()
+	}
+	return out, nil
+}
+
+// toFieldMap normalizes value, which should be a Go map produced by a caller
+// filling in an OBJECT column with struct-like field sub-schema, to
+// map[string]any.
+func toFieldMap(value any) (map[string]any, error) {
+	m, ok := value.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("expected an object value, got %T", value)
+	}
+	return m, nil
+}
+
+// mapEntry is a single key/value pair of an OBJECT column encoded as a MAP
+// (column.Key and column.Value both set, rather than column.Fields).
+type mapEntry struct {
+	key, value any
+}
+
+// toMapEntries normalizes value to a slice of mapEntry, for an OBJECT column
+// encoded as a MAP.
+func toMapEntries(value any) ([]mapEntry, error) {
+	m, ok := value.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("expected a map value, got %T", value)
+	}
+	entries := make([]mapEntry, 0, len(m))
+	for k, v := range m {
+		entries = append(entries, mapEntry{key: k, value: v})
+	}
+	return entries, nil
+}
@@ -0,0 +1,134 @@
+/*
+ * Copyright 2024 Redpanda Data, Inc.
+ *
+ * Licensed as a Redpanda Enterprise file under the Redpanda Community
+ * License (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * https://github.com/redpanda-data/redpanda/blob/master/licenses/rcl.md
+ */
+
+package streaming
+
+import (
+	"testing"
+
+	"github.com/redpanda-data/connect/v4/internal/impl/snowflake/streaming/int128"
+)
+
+func TestToAnySlice(t *testing.T) {
+	got, err := toAnySlice([]any{1, "two", true})
+	if err != nil {
+		t.Fatalf("toAnySlice returned error: %v", err)
+	}
+	if len(got) != 3 || got[1] != "two" {
+		t.Fatalf("unexpected result: %v", got)
+	}
+	if _, err := toAnySlice(42); err == nil {
+		t.Fatal("expected an error for a non-slice value")
+	}
+}
+
+func TestToFieldMap(t *testing.T) {
+	if _, err := toFieldMap("not a map"); err == nil {
+		t.Fatal("expected an error for a non-map value")
+	}
+	got, err := toFieldMap(map[string]any{"a": 1})
+	if err != nil {
+		t.Fatalf("toFieldMap returned error: %v", err)
+	}
+	if got["a"] != 1 {
+		t.Fatalf("unexpected result: %v", got)
+	}
+}
+
+func TestToMapEntries(t *testing.T) {
+	entries, err := toMapEntries(map[string]any{"k": "v"})
+	if err != nil {
+		t.Fatalf("toMapEntries returned error: %v", err)
+	}
+	if len(entries) != 1 || entries[0].key != "k" || entries[0].value != "v" {
+		t.Fatalf("unexpected result: %v", entries)
+	}
+}
+
+// TestStructuredConverterPopulatesElementStats verifies that converting an
+// ARRAY value runs every element through the per-element converter, so its
+// stats - not just the top-level column's - reflect what was written. This
+// is the behavior that was missing entirely before: a nil converter never
+// touched stats.children at all.
+func TestStructuredConverterPopulatesElementStats(t *testing.T) {
+	col := columnMetadata{
+		Name:        "tags",
+		Ordinal:     1,
+		Nullable:    true,
+		LogicalType: "ARRAY",
+		Element: &columnMetadata{
+			Name:        "element",
+			Ordinal:     2,
+			LogicalType: "BOOLEAN",
+		},
+	}
+	_, transformers, _, err := constructParquetSchema([]columnMetadata{col}, EncodingConfig{})
+	if err != nil {
+		t.Fatalf("constructParquetSchema returned error: %v", err)
+	}
+	transformer := transformers[normalizeColumnName(col.Name)]
+	if transformer.converter == nil {
+		t.Fatal("expected a non-nil converter for a structured ARRAY column")
+	}
+	if err := transformer.converter.ValidateAndConvert(transformer.stats, []any{true, false, true}, &transformer.buf); err != nil {
+		t.Fatalf("ValidateAndConvert returned error: %v", err)
+	}
+	element := transformer.children["element"]
+	if element.stats.nullCount != 0 {
+		t.Errorf("expected no nulls observed, got nullCount=%d", element.stats.nullCount)
+	}
+	if len(transformer.rows) != 1 {
+		t.Fatalf("expected one buffered row, got %d", len(transformer.rows))
+	}
+}
+
+// TestStructuredConverterEncodesDecimalElements guards the bug where the
+// bytes actually written came from a separate, generic-reflection encode of
+// the raw input instead of the per-element numberConverter: a DECIMAL array
+// element must come out scaled by its declared Scale, not as whatever
+// parquet-go's reflection would make of the raw string.
+func TestStructuredConverterEncodesDecimalElements(t *testing.T) {
+	scale, precision := int32(2), int32(5)
+	col := columnMetadata{
+		Name:        "amounts",
+		Ordinal:     1,
+		Nullable:    true,
+		LogicalType: "ARRAY",
+		Element: &columnMetadata{
+			Name:         "element",
+			Ordinal:      2,
+			LogicalType:  "FIXED",
+			PhysicalType: "SB2",
+			Scale:        &scale,
+			Precision:    &precision,
+		},
+	}
+	_, transformers, _, err := constructParquetSchema([]columnMetadata{col}, EncodingConfig{})
+	if err != nil {
+		t.Fatalf("constructParquetSchema returned error: %v", err)
+	}
+	transformer := transformers[normalizeColumnName(col.Name)]
+	if err := transformer.converter.ValidateAndConvert(transformer.stats, []any{"1.23", "-4.50"}, &transformer.buf); err != nil {
+		t.Fatalf("ValidateAndConvert returned error: %v", err)
+	}
+	if len(transformer.rows) != 1 {
+		t.Fatalf("expected one buffered row, got %d", len(transformer.rows))
+	}
+	row := transformer.rows[0]
+	if len(row) != 2 {
+		t.Fatalf("expected 2 leaf values for the two array elements, got %d", len(row))
+	}
+	// A FIXED column with a non-zero Scale is stored as a 16-byte
+	// big-endian FixedLenByteArray, see convertFixedType.
+	got0, got1 := int128.FromBigEndian(row[0].ByteArray()), int128.FromBigEndian(row[1].ByteArray())
+	if got0 != int128.Int64(123) || got1 != int128.Int64(-450) {
+		t.Errorf("expected scale-2 integers [123 -450], got [%v %v]", got0, got1)
+	}
+}
@@ -0,0 +1,145 @@
+/*
+ * Copyright 2024 Redpanda Data, Inc.
+ *
+ * Licensed as a Redpanda Enterprise file under the Redpanda Community
+ * License (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * https://github.com/redpanda-data/redpanda/blob/master/licenses/rcl.md
+ */
+
+package streaming
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// constructArrayNode builds a proper repeated/LIST-annotated parquet group
+// for an ARRAY column whose element type is known (column.Element is set),
+// instead of flattening it into a JSON string. It returns a nil node (and no
+// error) when the column carries no element sub-schema, signalling that the
+// caller should fall back to the existing JSON encoding - Snowflake doesn't
+// support the structured variant for every element type yet.
+func constructArrayNode(column columnMetadata) (parquet.Node, *dataTransformer, error) {
+	if column.Element == nil || !supportsStructuredType(*column.Element) {
+		return nil, nil, nil
+	}
+	elemNode, elemConverter, err := constructNestedLeaf(*column.Element)
+	if err != nil {
+		return nil, nil, fmt.Errorf("array element: %w", err)
+	}
+	elem := &dataTransformer{
+		converter: elemConverter,
+		stats:     &statsBuffer{columnID: int(column.Element.Ordinal)},
+		column:    column.Element,
+	}
+	// Nullability is applied once by the caller (constructParquetSchema),
+	// the same way it is for every other logical type - wrapping it here too
+	// would double-wrap the node in parquet.Optional.
+	return parquet.List(elemNode), elem, nil
+}
+
+// constructObjectNode builds a STRUCT (when column.Fields is set) or
+// MAP-annotated (when column.Key/column.Value are set) parquet group for an
+// OBJECT column, instead of flattening it into a JSON string. It returns a
+// nil node when the column carries no field sub-schema, signalling the
+// caller should fall back to the existing JSON encoding.
+//
+// The returned []string is the normalized field order for a STRUCT (nil for
+// a MAP), since the children map itself doesn't preserve it - callers need
+// that order to build a structuredConverter that writes fields deterministically.
+func constructObjectNode(column columnMetadata) (parquet.Node, map[string]*dataTransformer, []string, error) {
+	switch {
+	case column.Key != nil && column.Value != nil:
+		if !supportsStructuredType(*column.Key) || !supportsStructuredType(*column.Value) {
+			return nil, nil, nil, nil
+		}
+		keyNode, keyConverter, err := constructNestedLeaf(*column.Key)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("map key: %w", err)
+		}
+		valueNode, valueConverter, err := constructNestedLeaf(*column.Value)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("map value: %w", err)
+		}
+		// Nullability is applied once by the caller, see constructArrayNode.
+		n := parquet.Map(keyNode, valueNode)
+		children := map[string]*dataTransformer{
+			"key": {
+				converter: keyConverter,
+				stats:     &statsBuffer{columnID: int(column.Key.Ordinal)},
+				column:    column.Key,
+			},
+			"value": {
+				converter: valueConverter,
+				stats:     &statsBuffer{columnID: int(column.Value.Ordinal)},
+				column:    column.Value,
+			},
+		}
+		return n, children, nil, nil
+	case len(column.Fields) > 0:
+		group := parquet.Group{}
+		children := map[string]*dataTransformer{}
+		order := make([]string, 0, len(column.Fields))
+		for _, field := range column.Fields {
+			if !supportsStructuredType(field) {
+				return nil, nil, nil, nil
+			}
+			fieldNode, fieldConverter, err := constructNestedLeaf(field)
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("struct field %s: %w", field.Name, err)
+			}
+			name := normalizeColumnName(field.Name)
+			group[name] = fieldNode
+			children[name] = &dataTransformer{
+				converter: fieldConverter,
+				stats:     &statsBuffer{columnID: int(field.Ordinal)},
+				column:    &field,
+			}
+			order = append(order, name)
+		}
+		// Nullability is applied once by the caller, see constructArrayNode.
+		return group, children, order, nil
+	default:
+		return nil, nil, nil, nil
+	}
+}
+
+// constructNestedLeaf builds the parquet node and converter for a single
+// scalar element of a structured ARRAY/MAP/OBJECT column, reusing the same
+// per-type construction constructParquetSchema uses for top level columns.
+//
+// Structured encoding only goes one level deep: an element or field that's
+// itself an ARRAY or OBJECT is rejected by supportsStructuredType before
+// this is ever called, so the column falls back to the JSON blob encoding
+// instead of nesting further.
+func constructNestedLeaf(column columnMetadata) (parquet.Node, dataConverter, error) {
+	schema, transformers, _, err := constructParquetSchema([]columnMetadata{column}, EncodingConfig{})
+	if err != nil {
+		return nil, nil, err
+	}
+	name := normalizeColumnName(column.Name)
+	for _, field := range schema.Fields() {
+		if field.Name() == name {
+			return field, transformers[name].converter, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("unable to construct nested leaf for column %s", column.Name)
+}
+
+// supportsStructuredType reports whether column's logical type can be
+// encoded as a real parquet LIST/MAP/STRUCT element. JSON-only types
+// (variant) and further nesting (array/object elements) are excluded, so
+// callers fall back to the existing JSON blob encoding rather than building
+// a converter this package doesn't yet know how to write.
+func supportsStructuredType(column columnMetadata) bool {
+	switch strings.ToLower(column.LogicalType) {
+	case "variant", "array", "object":
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,71 @@
+/*
+ * Copyright 2024 Redpanda Data, Inc.
+ *
+ * Licensed as a Redpanda Enterprise file under the Redpanda Community
+ * License (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * https://github.com/redpanda-data/redpanda/blob/master/licenses/rcl.md
+ */
+
+package streaming
+
+import "testing"
+
+func TestSupportsStructuredType(t *testing.T) {
+	tests := []struct {
+		name string
+		col  columnMetadata
+		want bool
+	}{
+		{"text is structured-safe", columnMetadata{LogicalType: "TEXT"}, true},
+		{"fixed is structured-safe", columnMetadata{LogicalType: "FIXED"}, true},
+		{"variant falls back to json", columnMetadata{LogicalType: "VARIANT"}, false},
+		{"nested array falls back to json", columnMetadata{LogicalType: "ARRAY"}, false},
+		{"nested object falls back to json", columnMetadata{LogicalType: "OBJECT"}, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := supportsStructuredType(tc.col); got != tc.want {
+				t.Errorf("supportsStructuredType(%+v) = %v, want %v", tc.col, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestConstructArrayNodeRejectsNestedElement(t *testing.T) {
+	elem := columnMetadata{Name: "element", Ordinal: 2, LogicalType: "ARRAY"}
+	col := columnMetadata{Name: "col", Ordinal: 1, LogicalType: "ARRAY", Element: &elem}
+	node, transformer, err := constructArrayNode(col)
+	if err != nil {
+		t.Fatalf("constructArrayNode returned error: %v", err)
+	}
+	if node != nil || transformer != nil {
+		// A nested array-of-array element isn't supported, so the column
+		// must fall back to the JSON encoding rather than building a node.
+		t.Fatalf("expected nil node/transformer for a nested array element, got %v, %v", node, transformer)
+	}
+}
+
+func TestConstructObjectNodeStructFieldOrder(t *testing.T) {
+	fields := []columnMetadata{
+		{Name: "b", Ordinal: 2, LogicalType: "TEXT", PhysicalType: "LOB"},
+		{Name: "a", Ordinal: 3, LogicalType: "BOOLEAN"},
+	}
+	col := columnMetadata{Name: "col", Ordinal: 1, LogicalType: "OBJECT", Fields: fields}
+	node, children, order, err := constructObjectNode(col)
+	if err != nil {
+		t.Fatalf("constructObjectNode returned error: %v", err)
+	}
+	if node == nil {
+		t.Fatalf("expected a struct node for columns with Fields set")
+	}
+	if len(order) != len(fields) || order[0] != "b" || order[1] != "a" {
+		t.Fatalf("expected field order [b a], got %v", order)
+	}
+	for _, name := range order {
+		if children[name] == nil {
+			t.Errorf("missing child transformer for field %s", name)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+/*
+ * Copyright 2024 Redpanda Data, Inc.
+ *
+ * Licensed as a Redpanda Enterprise file under the Redpanda Community
+ * License (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * https://github.com/redpanda-data/redpanda/blob/master/licenses/rcl.md
+ */
+
+package streaming
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/redpanda-data/connect/v4/internal/impl/snowflake/streaming/int128"
+)
+
+// TestRoundTripBDEC writes a small batch with WriteBDEC and reads it back
+// with OpenBDECReader, the one thing chunk0-1 asked for ("round-tripping of
+// BDEC files") that had no coverage anywhere in the series.
+func TestRoundTripBDEC(t *testing.T) {
+	columns := []columnMetadata{
+		{Name: "id", Ordinal: 1, LogicalType: "fixed", PhysicalType: "SB4", Nullable: false},
+		{Name: "name", Ordinal: 2, LogicalType: "text", PhysicalType: "LOB", Nullable: true},
+	}
+	rows := []map[string]any{
+		{"id": int64(1), "name": "alice"},
+		{"id": int64(2), "name": nil},
+	}
+	var buf bytes.Buffer
+	if _, err := WriteBDEC(&buf, columns, EncodingConfig{}, WriterOptions{}, NewSliceRowSource(rows)); err != nil {
+		t.Fatalf("WriteBDEC returned error: %v", err)
+	}
+	data := buf.Bytes()
+	reader, err := OpenBDECReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("OpenBDECReader returned error: %v", err)
+	}
+	defer reader.Close()
+
+	var got []map[string]any
+	for {
+		row, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next returned error: %v", err)
+		}
+		got = append(got, row)
+	}
+	if len(got) != len(rows) {
+		t.Fatalf("got %d rows, want %d", len(got), len(rows))
+	}
+	if got[0]["id"] != int128.Int64(1) {
+		t.Errorf("row 0 id = %v, want 1", got[0]["id"])
+	}
+	if got[0]["name"] != "alice" {
+		t.Errorf("row 0 name = %v, want alice", got[0]["name"])
+	}
+	if got[1]["name"] != nil {
+		t.Errorf("row 1 name = %v, want nil", got[1]["name"])
+	}
+}
+
+// TestOpenBDECReaderRejectsStructuredColumns guards the bug the reader used
+// to have for a structured ARRAY/OBJECT column: parquet.Value.Column()
+// indices for a multi-leaf group no longer line up 1:1 with schema.Fields(),
+// so it must fail cleanly rather than panic or misattribute column metadata.
+func TestOpenBDECReaderRejectsStructuredColumns(t *testing.T) {
+	columns := []columnMetadata{
+		{
+			Name:        "tags",
+			Ordinal:     1,
+			Nullable:    true,
+			LogicalType: "ARRAY",
+			Element: &columnMetadata{
+				Name:        "element",
+				Ordinal:     2,
+				LogicalType: "BOOLEAN",
+			},
+		},
+	}
+	var buf bytes.Buffer
+	if _, err := WriteBDEC(&buf, columns, EncodingConfig{}, WriterOptions{}, NewSliceRowSource(nil)); err != nil {
+		t.Fatalf("WriteBDEC returned error: %v", err)
+	}
+	data := buf.Bytes()
+	if _, err := OpenBDECReader(bytes.NewReader(data), int64(len(data))); err == nil {
+		t.Fatal("expected OpenBDECReader to reject a structured ARRAY column")
+	}
+}
+
+// TestDecodeTimestampScale verifies decodeTimestamp scales by powers of 10,
+// not by bit-shifting - a scale-3 (millisecond) value must come back
+// multiplied by 1e6 to reach nanoseconds, not left-shifted by 6 bits.
+func TestDecodeTimestampScale(t *testing.T) {
+	scale := int32(3)
+	column := columnMetadata{LogicalType: "TIMESTAMP_NTZ", Scale: &scale}
+	decode := decodeTimestamp(column)
+
+	millis := int64(1_700_000_000_123)
+	got, err := decode(parquet.Int64Value(millis))
+	if err != nil {
+		t.Fatalf("decodeTimestamp returned error: %v", err)
+	}
+	want := time.Unix(0, millis*1_000_000).UTC()
+	gotTime, ok := got.(time.Time)
+	if !ok {
+		t.Fatalf("expected a time.Time, got %T", got)
+	}
+	if !gotTime.Equal(want) {
+		t.Errorf("decodeTimestamp(%d, scale=3) = %v, want %v", millis, gotTime, want)
+	}
+}
+
+// TestDecodeTimestampDefaultsToNanoseconds covers a column with no Scale at
+// all (e.g. one written before timestamp columns carried a Decimal
+// annotation), which should be treated as already-nanosecond rather than
+// silently assuming scale 0.
+func TestDecodeTimestampDefaultsToNanoseconds(t *testing.T) {
+	column := columnMetadata{LogicalType: "TIMESTAMP_NTZ"}
+	decode := decodeTimestamp(column)
+
+	nanos := int64(1_700_000_000_000_000_123)
+	got, err := decode(parquet.Int64Value(nanos))
+	if err != nil {
+		t.Fatalf("decodeTimestamp returned error: %v", err)
+	}
+	gotTime, ok := got.(time.Time)
+	if !ok {
+		t.Fatalf("expected a time.Time, got %T", got)
+	}
+	if want := time.Unix(0, nanos).UTC(); !gotTime.Equal(want) {
+		t.Errorf("decodeTimestamp(%d, scale=default) = %v, want %v", nanos, gotTime, want)
+	}
+}
+
+func TestDecimalString(t *testing.T) {
+	tests := []struct {
+		n     int64
+		scale int32
+		want  string
+	}{
+		{12345, 2, "123.45"},
+		{-12345, 2, "-123.45"},
+		{5, 2, "0.05"},
+		{100, 0, "100"},
+	}
+	for _, tc := range tests {
+		if got := decimalString(int128.Int64(tc.n), tc.scale); got != tc.want {
+			t.Errorf("decimalString(%d, %d) = %q, want %q", tc.n, tc.scale, got, tc.want)
+		}
+	}
+}
@@ -28,6 +28,16 @@ type dataTransformer struct {
 	stats     *statsBuffer
 	column    *columnMetadata
 	buf       typedBuffer
+	// children holds the sub-column transformers for a structured ARRAY or
+	// OBJECT column, keyed by the normalized name of the nested parquet
+	// field (e.g. "element" for a LIST, or the struct's field names). It's
+	// nil for leaf columns and for structured columns written as JSON.
+	children map[string]*dataTransformer
+	// rows holds the per-row, already-deconstructed parquet values for a
+	// structured ARRAY/OBJECT column (one []parquet.Value per row, in the
+	// order writeBufferedRow needs to splice them into the row it's
+	// building). It's nil for leaf columns, which use buf instead.
+	rows [][]parquet.Value
 }
 
 func convertFixedType(column columnMetadata) (parquet.Node, dataConverter, error) {
@@ -80,7 +90,7 @@ func convertFixedType(column columnMetadata) (parquet.Node, dataConverter, error
 const maxJSONSize = 16*humanize.MiByte - 64
 
 // See ParquetTypeGenerator
-func constructParquetSchema(columns []columnMetadata) (*parquet.Schema, map[string]*dataTransformer, map[string]string, error) {
+func constructParquetSchema(columns []columnMetadata, cfg EncodingConfig) (*parquet.Schema, map[string]*dataTransformer, map[string]string, error) {
 	groupNode := parquet.Group{}
 	transformers := map[string]*dataTransformer{}
 	typeMetadata := map[string]string{"sfVer": "1,1"}
@@ -89,6 +99,9 @@ func constructParquetSchema(columns []columnMetadata) (*parquet.Schema, map[stri
 		id := int(column.Ordinal)
 		var n parquet.Node
 		var converter dataConverter
+		var children map[string]*dataTransformer
+		var childStats []*statsBuffer
+		var structFieldOrder []string
 		logicalType := strings.ToLower(column.LogicalType)
 		switch logicalType {
 		case "fixed":
@@ -97,13 +110,32 @@ func constructParquetSchema(columns []columnMetadata) (*parquet.Schema, map[stri
 				return nil, nil, nil, err
 			}
 		case "array":
-			typeMetadata[fmt.Sprintf("%d:obj_enc", id)] = "1"
-			n = parquet.String()
-			converter = jsonArrayConverter{jsonConverter{column.Nullable, maxJSONSize}}
+			if structuredNode, structuredChildren, structuredErr := constructArrayNode(column); structuredErr != nil {
+				return nil, nil, nil, structuredErr
+			} else if structuredNode != nil {
+				n = structuredNode
+				children = map[string]*dataTransformer{"element": structuredChildren}
+				childStats = []*statsBuffer{structuredChildren.stats}
+			} else {
+				typeMetadata[fmt.Sprintf("%d:obj_enc", id)] = "1"
+				n = parquet.String()
+				converter = jsonArrayConverter{jsonConverter{column.Nullable, maxJSONSize}}
+			}
 		case "object":
-			typeMetadata[fmt.Sprintf("%d:obj_enc", id)] = "1"
-			n = parquet.String()
-			converter = jsonObjectConverter{jsonConverter{column.Nullable, maxJSONSize}}
+			if structuredNode, structuredChildren, structuredOrder, structuredErr := constructObjectNode(column); structuredErr != nil {
+				return nil, nil, nil, structuredErr
+			} else if structuredNode != nil {
+				n = structuredNode
+				children = structuredChildren
+				structFieldOrder = structuredOrder
+				for _, child := range structuredChildren {
+					childStats = append(childStats, child.stats)
+				}
+			} else {
+				typeMetadata[fmt.Sprintf("%d:obj_enc", id)] = "1"
+				n = parquet.String()
+				converter = jsonObjectConverter{jsonConverter{column.Nullable, maxJSONSize}}
+			}
 		case "variant":
 			typeMetadata[fmt.Sprintf("%d:obj_enc", id)] = "1"
 			n = parquet.String()
@@ -132,15 +164,23 @@ func constructParquetSchema(columns []columnMetadata) (*parquet.Schema, map[stri
 			n = parquet.Leaf(parquet.DoubleType)
 			converter = doubleConverter{column.Nullable}
 		case "timestamp_tz", "timestamp_ltz", "timestamp_ntz":
-			if column.PhysicalType == "SB8" {
-				n = parquet.Leaf(parquet.Int64Type)
-			} else {
-				n = parquet.Leaf(parquet.FixedLenByteArrayType(16))
+			// Scale defaults to nanoseconds, same as "time" below, when the
+			// caller doesn't specify one. It has to be carried as a Decimal
+			// logical type annotation (like FIXED/TIME already are) rather
+			// than left as a bare Int64/FixedLenByteArray leaf - otherwise
+			// it's never persisted in the BDEC file, and a reader has no way
+			// to know how to scale the raw integer back into a time.Time.
+			t := parquet.Int64Type
+			precision := int(maxPrecisionForByteWidth(8))
+			if column.PhysicalType != "SB8" {
+				t = parquet.FixedLenByteArrayType(16)
+				precision = int(maxPrecisionForByteWidth(16))
 			}
-			var scale int32
+			scale := int32(9)
 			if column.Scale != nil {
 				scale = *column.Scale
 			}
+			n = parquet.Decimal(int(scale), precision, t)
 			tz := logicalType != "timestamp_ntz"
 			converter = timestampConverter{column.Nullable, scale, tz}
 		case "time":
@@ -166,9 +206,12 @@ func constructParquetSchema(columns []columnMetadata) (*parquet.Schema, map[stri
 			n = parquet.Optional(n)
 		}
 		n = parquet.FieldID(n, id)
-		// Use plain encoding for now as there seems to be compatibility issues with the default settings
-		// we might be able to tune this more.
-		n = parquet.Encoded(n, &parquet.Plain)
+		// The real encoding decision happens in narrowPhysicalTypes once we
+		// have stats to sample from; at construction time the policy is
+		// consulted with nil stats, so only cfg.Overrides and choices that
+		// need no data apply yet (under the default policy: RLE_DICTIONARY
+		// for booleans, PLAIN for everything else).
+		n = parquet.Encoded(n, cfg.selectEncoding(&column, nil))
 		typeMetadata[strconv.Itoa(id)] = fmt.Sprintf(
 			"%d,%d",
 			logicalTypeOrdinal(column.LogicalType),
@@ -176,12 +219,22 @@ func constructParquetSchema(columns []columnMetadata) (*parquet.Schema, map[stri
 		)
 		name := normalizeColumnName(column.Name)
 		groupNode[name] = n
-		transformers[name] = &dataTransformer{
+		t := &dataTransformer{
 			name:      column.Name,
 			converter: converter,
-			stats:     &statsBuffer{columnID: id},
+			stats:     &statsBuffer{columnID: id, children: childStats, first: true},
 			column:    &column,
+			children:  children,
+		}
+		if children != nil {
+			// A structured ARRAY/OBJECT column: converter is still nil above
+			// since constructArrayNode/constructObjectNode only build the
+			// schema node and the per-element/field transformers. Give it a
+			// real converter so appendRow/writeBufferedRow can treat it like
+			// any other column instead of hitting a nil interface.
+			t.converter = newStructuredConverter(t, column, n, children, structFieldOrder)
 		}
+		transformers[name] = t
 	}
 	return parquet.NewSchema("bdec", groupNode), transformers, typeMetadata, nil
 }
@@ -191,33 +244,47 @@ func constructParquetSchema(columns []columnMetadata) (*parquet.Schema, map[stri
 func narrowPhysicalTypes(
 	schema *parquet.Schema,
 	transformers map[string]*dataTransformer,
-	fileMetadata map[string]string) (*parquet.Schema, map[string]string) {
+	fileMetadata map[string]string,
+	cfg EncodingConfig) (*parquet.Schema, map[string]string) {
 	mapped := parquet.Group{}
 	mappedMeta := maps.Clone(fileMetadata)
 	for _, field := range schema.Fields() {
 		name := field.Name()
 		t := transformers[name]
 		if !canCompatNumber(t.column) {
-			mapped[field.Name()] = field
+			n := field
+			encoding := cfg.selectEncoding(t.column, t.stats)
+			if encoding.Encoding() != field.Encoding().Encoding() {
+				n = parquet.Encoded(field, encoding)
+			}
+			mapped[field.Name()] = n
 			continue
 		}
 		stats := transformers[field.Name()].stats
-		byteWidth := max(int128.ByteWidth(stats.maxIntVal), int128.ByteWidth(stats.minIntVal))
+		byteWidth := narrowedByteWidth(field, stats)
+		if byteWidth == 16 {
+			// parquet.Int only builds 8/16/32/64-bit integer nodes, so a
+			// column that still needs all 16 bytes keeps its original node.
+			mapped[field.Name()] = field
+			continue
+		}
 		n := parquet.Int(byteWidth * 8)
 		if field.Type().LogicalType() != nil && field.Type().LogicalType().Decimal != nil {
 			d := field.Type().LogicalType().Decimal
-			n = parquet.Decimal(
-				int(d.Scale),
-				int(min(d.Precision, maxPrecisionForByteWidth(byteWidth))),
-				n.Type(),
-			)
+			precision := int32(d.Precision)
+			if isDecimalColumn(t.column) {
+				precision = tightestDecimalPrecision(stats, byteWidth, int32(d.Precision))
+			} else {
+				precision = min(precision, maxPrecisionForByteWidth(byteWidth))
+			}
+			n = parquet.Decimal(int(d.Scale), int(precision), n.Type())
 		}
 		if field.Optional() {
 			n = parquet.Optional(n)
 		}
 		n = parquet.FieldID(n, field.ID())
 		n = parquet.Compressed(n, field.Compression())
-		n = parquet.Encoded(n, field.Encoding())
+		n = parquet.Encoded(n, cfg.selectEncoding(t.column, stats))
 		mapped[field.Name()] = n
 		mappedMeta[strconv.Itoa(field.ID())] = fmt.Sprintf(
 			"%d,%d",
@@ -243,6 +310,15 @@ type statsBuffer struct {
 	maxStrLen              int
 	nullCount              int64
 	first                  bool
+	// children holds the per-level stats for a structured ARRAY/OBJECT
+	// column, one entry per nested leaf, so that EP stats (min/max, null
+	// counts) are still tracked on the actual element/field values instead
+	// of being lost to the enclosing JSON blob.
+	children []*statsBuffer
+	// distinct estimates the column's cardinality so the encoding policy
+	// can decide whether DICTIONARY encoding is worthwhile. It's nil until
+	// the first value is observed.
+	distinct *cardinalitySketch
 }
 
 func (s *statsBuffer) Reset() {
@@ -255,6 +331,7 @@ func (s *statsBuffer) Reset() {
 	s.maxStrVal = nil
 	s.maxStrLen = 0
 	s.nullCount = 0
+	s.distinct = nil
 }
 
 func computeColumnEpInfo(stats map[string]*dataTransformer) map[string]fileColumnProperties {
@@ -321,6 +398,64 @@ func physicalTypeOrdinal(str string) int {
 	return -1
 }
 
+func physicalTypeFromOrdinal(ordinal int) string {
+	switch ordinal {
+	case 9:
+		return "ROWINDEX"
+	case 7:
+		return "DOUBLE"
+	case 1:
+		return "SB1"
+	case 2:
+		return "SB2"
+	case 3:
+		return "SB4"
+	case 4:
+		return "SB8"
+	case 5:
+		return "SB16"
+	case 8:
+		return "LOB"
+	case 10:
+		return "ROW"
+	}
+	return ""
+}
+
+func logicalTypeFromOrdinal(ordinal int) string {
+	switch ordinal {
+	case 1:
+		return "BOOLEAN"
+	case 15:
+		return "NULL"
+	case 8:
+		return "REAL"
+	case 2:
+		return "FIXED"
+	case 9:
+		return "TEXT"
+	case 10:
+		return "BINARY"
+	case 7:
+		return "DATE"
+	case 6:
+		return "TIME"
+	case 3:
+		return "TIMESTAMP_LTZ"
+	case 4:
+		return "TIMESTAMP_NTZ"
+	case 5:
+		return "TIMESTAMP_TZ"
+	case 13:
+		return "ARRAY"
+	case 12:
+		return "OBJECT"
+	case 11:
+		return "VARIANT"
+	}
+	return ""
+}
+
 func logicalTypeOrdinal(str string) int {
 	switch strings.ToUpper(str) {
 	case "BOOLEAN":
@@ -0,0 +1,309 @@
+/*
+ * Copyright 2024 Redpanda Data, Inc.
+ *
+ * Licensed as a Redpanda Enterprise file under the Redpanda Community
+ * License (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * https://github.com/redpanda-data/redpanda/blob/master/licenses/rcl.md
+ */
+
+package streaming
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// defaultMaxInMemoryRows is used when WriterOptions.MaxInMemoryRows is left
+// at its zero value. It's deliberately conservative - callers ingesting
+// multi-GB batches should set this based on their own row size.
+const defaultMaxInMemoryRows = 1_000_000
+
+// WriterOptions configures the two-phase BDEC writer. The zero value selects
+// the in-memory fast path for any batch up to defaultMaxInMemoryRows and
+// spills to os.TempDir() beyond that.
+type WriterOptions struct {
+	// MaxInMemoryRows is the largest batch that's buffered fully in memory
+	// before narrowPhysicalTypes runs. Batches larger than this are written
+	// to a spill file first. Zero means defaultMaxInMemoryRows.
+	MaxInMemoryRows int
+	// SpillDir is the directory used for the phase 1 provisional file. Empty
+	// means os.TempDir().
+	SpillDir string
+}
+
+func (o WriterOptions) maxInMemoryRows() int {
+	if o.MaxInMemoryRows > 0 {
+		return o.MaxInMemoryRows
+	}
+	return defaultMaxInMemoryRows
+}
+
+// RowSource yields the rows of a batch one at a time, so a writer never has
+// to hold the whole batch in memory itself. Next returns ok=false once the
+// source is exhausted.
+type RowSource interface {
+	Next() (row map[string]any, ok bool, err error)
+}
+
+// sliceRowSource adapts an in-memory slice of rows to RowSource, for callers
+// that already have their batch materialized.
+type sliceRowSource struct {
+	rows []map[string]any
+	pos  int
+}
+
+// NewSliceRowSource wraps rows, an already materialized batch, as a RowSource.
+func NewSliceRowSource(rows []map[string]any) RowSource {
+	return &sliceRowSource{rows: rows}
+}
+
+func (s *sliceRowSource) Next() (map[string]any, bool, error) {
+	if s.pos >= len(s.rows) {
+		return nil, false, nil
+	}
+	row := s.rows[s.pos]
+	s.pos++
+	return row, true, nil
+}
+
+// WriteBDEC writes rows as a single BDEC parquet file to dst, narrowing
+// physical types and picking encodings the same way regardless of whether
+// the batch fits in memory.
+//
+// For batches up to opts.maxInMemoryRows, every row is buffered in memory so
+// narrowPhysicalTypes can run against the real stats before anything is
+// written out - this is the existing, simpler path. Beyond that threshold,
+// rows are written once to a provisional spill file using the wide schema
+// while stats accumulate, then copied into a second file against the
+// narrowed schema; this bounds memory use for multi-GB batches at the cost
+// of writing each row twice. This mirrors the split-phase writer pattern
+// used by the Arrow Go parquet implementation.
+func WriteBDEC(dst io.Writer, columns []columnMetadata, cfg EncodingConfig, opts WriterOptions, rows RowSource) (map[string]string, error) {
+	schema, transformers, typeMetadata, err := constructParquetSchema(columns, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("constructing parquet schema: %w", err)
+	}
+
+	spillPath, rowCount, err := spillRows(schema, transformers, opts, rows)
+	if spillPath != "" {
+		defer os.Remove(spillPath)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	narrowedSchema, narrowedMeta := narrowPhysicalTypes(schema, transformers, typeMetadata, cfg)
+	writerOpts := make([]parquet.WriterOption, 0, len(narrowedMeta))
+	for k, v := range narrowedMeta {
+		writerOpts = append(writerOpts, parquet.KeyValueMetadata(k, v))
+	}
+
+	if spillPath == "" {
+		// The fast path: everything fit in memory, rows is now exhausted
+		// from the first (and only) pass, and we can write narrowed rows
+		// directly from the in-memory buffers accumulated in transformers.
+		return narrowedMeta, writeNarrowedFromBuffers(dst, narrowedSchema, transformers, rowCount, writerOpts...)
+	}
+	return narrowedMeta, rewriteNarrowed(dst, spillPath, narrowedSchema, writerOpts...)
+}
+
+// spillRows consumes rows, feeding each one through its column's converter
+// (which updates transformers[*].stats as a side effect). If the batch stays
+// within opts.maxInMemoryRows, buffered values accumulate in each
+// transformer's typedBuffer and spillRows returns "" with no file written.
+// Otherwise it flushes everything written so far, plus the remainder of
+// rows, to a provisional parquet file using schema and returns its path.
+func spillRows(
+	schema *parquet.Schema,
+	transformers map[string]*dataTransformer,
+	opts WriterOptions,
+	rows RowSource,
+) (spillPath string, rowCount int, err error) {
+	limit := opts.maxInMemoryRows()
+	var spill *os.File
+	var writer *parquet.Writer
+	for {
+		row, ok, err := rows.Next()
+		if err != nil {
+			return spillPath, rowCount, fmt.Errorf("reading row %d: %w", rowCount, err)
+		}
+		if !ok {
+			break
+		}
+		if spill == nil && rowCount >= limit {
+			spill, err = os.CreateTemp(opts.SpillDir, "bdec-spill-*.parquet")
+			if err != nil {
+				return "", rowCount, fmt.Errorf("creating spill file: %w", err)
+			}
+			spillPath = spill.Name()
+			writer = parquet.NewWriter(spill, schema)
+			if err := flushBufferedRows(writer, schema, transformers, rowCount); err != nil {
+				return spillPath, rowCount, err
+			}
+			// Every row buffered so far is now durable on disk - drop it from
+			// memory so the rest of the batch doesn't keep accumulating
+			// alongside what's still to come.
+			resetBuffers(transformers)
+		}
+		if err := appendRow(transformers, row); err != nil {
+			return spillPath, rowCount, fmt.Errorf("row %d: %w", rowCount, err)
+		}
+		if writer != nil {
+			// Once spilling, every transformer's buffer was just drained down
+			// to empty, so the row appendRow added above always lives at
+			// index 0.
+			if err := writeBufferedRow(writer, schema, transformers, 0); err != nil {
+				return spillPath, rowCount, err
+			}
+			resetBuffers(transformers)
+		}
+		rowCount++
+	}
+	if writer != nil {
+		if err := writer.Close(); err != nil {
+			return spillPath, rowCount, fmt.Errorf("closing spill file: %w", err)
+		}
+	}
+	return spillPath, rowCount, nil
+}
+
+// rewriteNarrowed copies every column chunk out of the wide-schema spill
+// file at spillPath into dst using narrowedSchema, re-encoding the columns
+// narrowPhysicalTypes narrowed down and copying the rest verbatim.
+//
+// A row read back from the spill file carries values typed against the wide
+// schema - an Int64, say, or a 16-byte FixedLenByteArray DECIMAL - and
+// narrowPhysicalTypes may have since changed that column's Kind (e.g. down
+// to Int32). parquet.Value doesn't reinterpret itself across Kinds, so
+// writing it straight into a writer built from narrowedSchema would either
+// panic or silently misread the raw bytes; every value whose column's Kind
+// changed is decoded against its original Kind and re-encoded against the
+// narrowed one before it's written.
+func rewriteNarrowed(dst io.Writer, spillPath string, narrowedSchema *parquet.Schema, opts ...parquet.WriterOption) error {
+	spill, err := os.Open(spillPath)
+	if err != nil {
+		return fmt.Errorf("reopening spill file: %w", err)
+	}
+	defer spill.Close()
+	info, err := spill.Stat()
+	if err != nil {
+		return fmt.Errorf("statting spill file: %w", err)
+	}
+	src, err := parquet.OpenFile(spill, info.Size())
+	if err != nil {
+		return fmt.Errorf("opening spill file: %w", err)
+	}
+	wideKinds := leafKinds(src.Schema())
+	narrowedKinds := leafKinds(narrowedSchema)
+	writer := parquet.NewWriter(dst, append([]parquet.WriterOption{narrowedSchema}, opts...)...)
+	reader := parquet.NewReader(src, src.Schema())
+	rowBuf := make(parquet.Row, 0, len(narrowedSchema.Fields()))
+	for {
+		rowBuf, err = reader.ReadRow(rowBuf[:0])
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("reading spilled row: %w", err)
+		}
+		for i, v := range rowBuf {
+			col := v.Column()
+			if col >= len(wideKinds) || col >= len(narrowedKinds) {
+				continue
+			}
+			if wideKinds[col] != narrowedKinds[col] {
+				rowBuf[i] = narrowNumericValue(v, narrowedKinds[col])
+			}
+		}
+		if _, err := writer.WriteRows([]parquet.Row{rowBuf}); err != nil {
+			return fmt.Errorf("writing narrowed row: %w", err)
+		}
+	}
+	return writer.Close()
+}
+
+// resetBuffers drops every transformer's buffered row data immediately after
+// it's been written out, so a batch larger than opts.MaxInMemoryRows holds at
+// most one pending row in memory at a time once spilling starts, instead of
+// retaining the whole batch for the rest of the write - the exact memory
+// bound spilling exists to provide.
+func resetBuffers(transformers map[string]*dataTransformer) {
+	for _, t := range transformers {
+		t.buf.Reset()
+		t.rows = t.rows[:0]
+		for _, child := range t.children {
+			child.buf.Reset()
+		}
+	}
+}
+
+// appendRow, flushBufferedRows and writeBufferedRow are the seams between
+// this file's batching logic and the per-column converter/typedBuffer
+// machinery that actually encodes Go values into parquet rows. They're
+// intentionally thin - the conversion itself lives alongside the converters
+// in schema.go.
+func appendRow(transformers map[string]*dataTransformer, row map[string]any) error {
+	for name, t := range transformers {
+		if t.converter == nil {
+			return fmt.Errorf("column %s: no converter configured", name)
+		}
+		value := row[name]
+		if err := t.converter.ValidateAndConvert(t.stats, value, &t.buf); err != nil {
+			return fmt.Errorf("column %s: %w", name, err)
+		}
+		if cardinalityEligible(t.column) {
+			observeCardinality(t.stats, value)
+		}
+	}
+	return nil
+}
+
+func flushBufferedRows(writer *parquet.Writer, schema *parquet.Schema, transformers map[string]*dataTransformer, count int) error {
+	for i := 0; i < count; i++ {
+		if err := writeBufferedRow(writer, schema, transformers, i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// columnValues returns the parquet values idx'th row contributes for field,
+// in the order they belong in a parquet.Row. A leaf column contributes
+// exactly one value, from its typedBuffer; a structured ARRAY/OBJECT column
+// contributes however many leaf values its own converter deconstructed it
+// into, from t.rows.
+func columnValues(t *dataTransformer, idx int) []parquet.Value {
+	if t.children != nil {
+		if idx < len(t.rows) {
+			return t.rows[idx]
+		}
+		return nil
+	}
+	return []parquet.Value{t.buf.Value(idx)}
+}
+
+func writeBufferedRow(writer *parquet.Writer, schema *parquet.Schema, transformers map[string]*dataTransformer, idx int) error {
+	row := make(parquet.Row, 0, len(schema.Fields()))
+	for _, field := range schema.Fields() {
+		row = append(row, columnValues(transformers[field.Name()], idx)...)
+	}
+	_, err := writer.WriteRows([]parquet.Row{row})
+	return err
+}
+
+// writeNarrowedFromBuffers writes every row accumulated across
+// transformers[*].buf, in the narrowed schema's field order. This is the
+// in-memory fast path, used when the whole batch fit within
+// opts.maxInMemoryRows.
+func writeNarrowedFromBuffers(dst io.Writer, schema *parquet.Schema, transformers map[string]*dataTransformer, rowCount int, opts ...parquet.WriterOption) error {
+	writer := parquet.NewWriter(dst, append([]parquet.WriterOption{schema}, opts...)...)
+	if err := flushBufferedRows(writer, schema, transformers, rowCount); err != nil {
+		return err
+	}
+	return writer.Close()
+}
@@ -0,0 +1,86 @@
+/*
+ * Copyright 2024 Redpanda Data, Inc.
+ *
+ * Licensed as a Redpanda Enterprise file under the Redpanda Community
+ * License (the "License"); you may not use this file except in compliance with
+ * the License. You may obtain a copy of the License at
+ *
+ * https://github.com/redpanda-data/redpanda/blob/master/licenses/rcl.md
+ */
+
+package streaming
+
+import (
+	"os"
+	"testing"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// TestAppendRowRejectsNilConverter guards the specific panic a structured
+// ARRAY/OBJECT column used to hit: before the nil converter was given a real
+// implementation, appendRow called t.converter.ValidateAndConvert on a nil
+// interface unconditionally. It should now fail cleanly instead of panicking
+// if a transformer is ever missing a converter again.
+func TestAppendRowRejectsNilConverter(t *testing.T) {
+	transformers := map[string]*dataTransformer{
+		"broken": {stats: &statsBuffer{}},
+	}
+	err := appendRow(transformers, map[string]any{"broken": "value"})
+	if err == nil {
+		t.Fatal("expected an error for a transformer with a nil converter, got nil")
+	}
+}
+
+// TestColumnValuesStructuredColumn verifies a structured column's buffered
+// rows are spliced in instead of being read off its (unused) typedBuffer.
+func TestColumnValuesStructuredColumn(t *testing.T) {
+	rowValues := []parquet.Value{parquet.BooleanValue(true), parquet.BooleanValue(false)}
+	transformer := &dataTransformer{
+		children: map[string]*dataTransformer{"element": {}},
+		rows:     [][]parquet.Value{rowValues},
+	}
+	got := columnValues(transformer, 0)
+	if len(got) != 2 {
+		t.Fatalf("expected 2 spliced values, got %d", len(got))
+	}
+}
+
+// TestSpillRowsBoundsMemory drives a batch past MaxInMemoryRows and checks
+// that a transformer's buffer never holds more than a single pending row once
+// spilling starts. Before this fix, appendRow kept writing every row into
+// t.buf/t.rows for the rest of the batch regardless of whether it had already
+// been flushed to the spill file - the exact unbounded-memory growth spilling
+// was supposed to prevent.
+func TestSpillRowsBoundsMemory(t *testing.T) {
+	columns := []columnMetadata{
+		{Name: "n", Ordinal: 1, LogicalType: "fixed", PhysicalType: "SB4", Nullable: false},
+	}
+	schema, transformers, _, err := constructParquetSchema(columns, EncodingConfig{})
+	if err != nil {
+		t.Fatalf("constructParquetSchema returned error: %v", err)
+	}
+	const totalRows = 10
+	rows := make([]map[string]any, totalRows)
+	for i := range rows {
+		rows[i] = map[string]any{"n": int64(i)}
+	}
+	opts := WriterOptions{MaxInMemoryRows: 2}
+	spillPath, rowCount, err := spillRows(schema, transformers, opts, NewSliceRowSource(rows))
+	if spillPath != "" {
+		defer os.Remove(spillPath)
+	}
+	if err != nil {
+		t.Fatalf("spillRows returned error: %v", err)
+	}
+	if rowCount != totalRows {
+		t.Fatalf("rowCount = %d, want %d", rowCount, totalRows)
+	}
+	if spillPath == "" {
+		t.Fatal("expected spillRows to create a spill file once MaxInMemoryRows was exceeded")
+	}
+	transformer := transformers[normalizeColumnName("n")]
+	if got := transformer.buf.Len(); got > 1 {
+		t.Errorf("buf.Len() = %d, want <= 1 once every row past MaxInMemoryRows has been spilled", got)
+	}
+}